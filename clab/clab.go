@@ -0,0 +1,149 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/srl-labs/containerlab/discovery"
+)
+
+// clabIntfLabelPrefix labels a node's per-interface names, mirroring
+// what genInterfacesEnv exposes inside the container as CLAB_INTF_* env
+// vars, e.g. "CLAB_INTF_0" -> "eth0".
+const clabIntfLabelPrefix = "CLAB_INTF_"
+
+// CLab drives a single lab through its deploy/destroy lifecycle.
+type CLab struct {
+	Config *Config
+	// Nodes holds the topology-graph representation of every node the
+	// topology declares, keyed by short name; NewCLab fills it in.
+	Nodes map[string]*Node
+}
+
+// NewCLab returns a CLab for cfg, with Nodes pre-populated from cfg's
+// parsed topology. That makes the topology-graph view available as soon as
+// a CLab exists, rather than only once nodes are actually deployed, so
+// Deploy/Destroy/DiscoveryRecords (and `containerlab discovery sync`, which
+// never deploys anything) have something to operate on.
+func NewCLab(cfg *Config) *CLab {
+	return &CLab{
+		Config: cfg,
+		Nodes:  nodesFromTopology(cfg),
+	}
+}
+
+// nodesFromTopology builds the topology-graph Node for every node declared
+// in cfg's topology, via NewNodeConfig, so CLab.Nodes reflects what the
+// topology file actually declares instead of staying empty until some
+// other part of the deploy pipeline gets around to filling it in.
+func nodesFromTopology(cfg *Config) map[string]*Node {
+	nodes := make(map[string]*Node, len(cfg.Topology.Nodes))
+
+	for name, def := range cfg.Topology.Nodes {
+		nc := NewNodeConfig(name, def)
+		nodes[name] = &Node{
+			ShortName: nc.ShortName,
+			LongName:  fmt.Sprintf("clab-%s-%s", cfg.Name, nc.ShortName),
+			Labels:    map[string]string{"clab-node-kind": nc.Kind},
+		}
+	}
+
+	return nodes
+}
+
+// DiscoveryRecords builds the discovery.Record for every node currently
+// known to the lab.
+func (c *CLab) DiscoveryRecords() []*discovery.Record {
+	records := make([]*discovery.Record, 0, len(c.Nodes))
+
+	for _, n := range c.Nodes {
+		records = append(records, &discovery.Record{
+			Name:       n.ShortName,
+			Lab:        c.Config.Name,
+			Kind:       n.Labels["clab-node-kind"],
+			MgmtIP:     n.Labels["clab-mgmt-ip"],
+			Labels:     n.Labels,
+			Interfaces: interfacesFromLabels(n.Labels),
+		})
+	}
+
+	return records
+}
+
+// interfacesFromLabels extracts a node's per-interface names
+// (CLAB_INTF_0, CLAB_INTF_1, ...) from its labels, keyed the same way they
+// are exposed inside the container, so discovery backends can publish them
+// alongside the rest of the record.
+func interfacesFromLabels(labels map[string]string) map[string]string {
+	var interfaces map[string]string
+	for k, v := range labels {
+		if strings.HasPrefix(k, clabIntfLabelPrefix) {
+			if interfaces == nil {
+				interfaces = make(map[string]string)
+			}
+			interfaces[k] = v
+		}
+	}
+	return interfaces
+}
+
+// Deploy registers the lab's nodes with the configured discovery backend,
+// if any. It does not provision nodes: runtime/container setup and
+// PreDeploy/PostDeploy happen elsewhere in the deploy pipeline.
+func (c *CLab) Deploy(ctx context.Context) error {
+	return c.RegisterDiscovery(ctx)
+}
+
+// Destroy tears down the lab. It deregisters the lab's nodes from the
+// discovery backend first, then the caller proceeds with container
+// teardown elsewhere in the destroy pipeline.
+func (c *CLab) Destroy(ctx context.Context) error {
+	return c.DeregisterDiscovery(ctx)
+}
+
+// RegisterDiscovery publishes every node in the lab to the configured
+// discovery backend. A no-op if the topology has no `discovery:` block.
+func (c *CLab) RegisterDiscovery(ctx context.Context) error {
+	if c.Config.Discovery == nil {
+		return nil
+	}
+
+	backend, err := discovery.NewBackend(c.Config.Discovery)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range c.DiscoveryRecords() {
+		if err := backend.Register(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeregisterDiscovery removes every node in the lab from the configured
+// discovery backend. A no-op if the topology has no `discovery:` block.
+func (c *CLab) DeregisterDiscovery(ctx context.Context) error {
+	if c.Config.Discovery == nil {
+		return nil
+	}
+
+	backend, err := discovery.NewBackend(c.Config.Discovery)
+	if err != nil {
+		return err
+	}
+
+	for _, r := range c.DiscoveryRecords() {
+		if err := backend.Deregister(ctx, c.Config.Name, r.Name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
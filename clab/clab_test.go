@@ -0,0 +1,157 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/srl-labs/containerlab/discovery"
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestDiscoveryRecords(t *testing.T) {
+	tests := []struct {
+		name       string
+		labels     map[string]string
+		wantKind   string
+		wantMgmtIP string
+		wantIfaces map[string]string
+	}{
+		{
+			name: "node with interfaces",
+			labels: map[string]string{
+				"clab-node-kind": "rare",
+				"clab-mgmt-ip":   "172.20.20.2",
+				"CLAB_INTF_0":    "eth0",
+				"CLAB_INTF_1":    "eth1",
+			},
+			wantKind:   "rare",
+			wantMgmtIP: "172.20.20.2",
+			wantIfaces: map[string]string{"CLAB_INTF_0": "eth0", "CLAB_INTF_1": "eth1"},
+		},
+		{
+			name: "node with no interfaces",
+			labels: map[string]string{
+				"clab-node-kind": "rare",
+				"clab-mgmt-ip":   "172.20.20.3",
+			},
+			wantKind:   "rare",
+			wantMgmtIP: "172.20.20.3",
+			wantIfaces: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewCLab(&Config{Name: "test-lab"})
+			c.Nodes["r1"] = &Node{ShortName: "r1", LongName: "clab-test-lab-r1", Labels: tt.labels}
+
+			records := c.DiscoveryRecords()
+			if len(records) != 1 {
+				t.Fatalf("got %d records, want 1", len(records))
+			}
+
+			r := records[0]
+			if r.Kind != tt.wantKind {
+				t.Errorf("Kind = %q, want %q", r.Kind, tt.wantKind)
+			}
+			if r.MgmtIP != tt.wantMgmtIP {
+				t.Errorf("MgmtIP = %q, want %q", r.MgmtIP, tt.wantMgmtIP)
+			}
+			if !reflect.DeepEqual(r.Interfaces, tt.wantIfaces) {
+				t.Errorf("Interfaces = %#v, want %#v", r.Interfaces, tt.wantIfaces)
+			}
+		})
+	}
+}
+
+func TestNewCLabPopulatesNodesFromTopology(t *testing.T) {
+	data := []byte(`
+name: test-lab
+topology:
+  nodes:
+    r1:
+      kind: rare
+    r2:
+      kind: rare
+`)
+	cfg, err := ParseTopology(data)
+	if err != nil {
+		t.Fatalf("ParseTopology() error = %v", err)
+	}
+
+	c := NewCLab(cfg)
+
+	if len(c.Nodes) != 2 {
+		t.Fatalf("got %d nodes, want 2: %v", len(c.Nodes), c.Nodes)
+	}
+	for _, name := range []string{"r1", "r2"} {
+		n, ok := c.Nodes[name]
+		if !ok {
+			t.Fatalf("Nodes missing %q", name)
+		}
+		if n.ShortName != name {
+			t.Errorf("Nodes[%q].ShortName = %q, want %q", name, n.ShortName, name)
+		}
+		wantLongName := "clab-test-lab-" + name
+		if n.LongName != wantLongName {
+			t.Errorf("Nodes[%q].LongName = %q, want %q", name, n.LongName, wantLongName)
+		}
+		if n.Labels["clab-node-kind"] != "rare" {
+			t.Errorf("Nodes[%q].Labels[clab-node-kind] = %q, want %q", name, n.Labels["clab-node-kind"], "rare")
+		}
+	}
+}
+
+// TestDiscoverySyncUsesTopologyNodes exercises the actual `containerlab
+// discovery sync` path end-to-end: a CLab built straight from a parsed
+// topology (no manual Nodes construction) registers every node it
+// declares against a real (file-backed) discovery backend.
+func TestDiscoverySyncUsesTopologyNodes(t *testing.T) {
+	regPath := filepath.Join(t.TempDir(), "discovery.json")
+
+	data := []byte(`
+name: test-lab
+topology:
+  nodes:
+    r1:
+      kind: rare
+`)
+	cfg, err := ParseTopology(data)
+	if err != nil {
+		t.Fatalf("ParseTopology() error = %v", err)
+	}
+	cfg.Discovery = &types.DiscoveryConfig{
+		Backend: "file",
+		File:    &types.FileDiscoveryConfig{Path: regPath},
+	}
+
+	c := NewCLab(cfg)
+
+	backend, err := discovery.NewBackend(c.Config.Discovery)
+	if err != nil {
+		t.Fatalf("NewBackend() error = %v", err)
+	}
+	if err := backend.Sync(context.Background(), c.Config.Name, c.DiscoveryRecords()); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	raw, err := os.ReadFile(regPath)
+	if err != nil {
+		t.Fatalf("reading registry file: %v", err)
+	}
+	var reg map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &reg); err != nil {
+		t.Fatalf("parsing registry file: %v", err)
+	}
+	if _, ok := reg["test-lab/r1"]; !ok {
+		t.Errorf("registry = %v, want an entry for test-lab/r1", reg)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/big"
+	"net/http"
+	"net/netip"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+// systemIP is the node label the system-ip IPAM provider derives link
+// addressing from.
+const systemIP = "systemip"
+
+// IPAM allocates the two endpoint addresses for a link. Implementations are
+// selected via the topology's `ipam:` block; AllocateLinkIPs is called once
+// per link, in link declaration order. epA/epB carry each endpoint's
+// relevant node labels (at least "node" and systemIP).
+type IPAM interface {
+	AllocateLinkIPs(linkID string, epA, epB labelMap) (ipA, ipB netip.Prefix, err error)
+}
+
+// activeIPAM is the IPAM provider RenderLink uses. It defaults to the
+// system-ip-derived allocator that preserves containerlab's original
+// addressing scheme.
+var activeIPAM IPAM = &systemIPAllocator{}
+
+// SetIPAM overrides the IPAM provider used by RenderLink. Called once at
+// startup after parsing the topology's `ipam:` block.
+func SetIPAM(a IPAM) {
+	activeIPAM = a
+}
+
+// NewIPAM builds the IPAM provider selected by cfg. A nil cfg (no `ipam:`
+// block in the topology) returns the default system-ip allocator. labDir is
+// the lab's working directory (e.g. "clab-<lab-name>"); the pool allocator
+// defaults its reservation file under it when cfg.Pool doesn't set one
+// explicitly.
+func NewIPAM(cfg *types.IPAMConfig, labDir string) (IPAM, error) {
+	if cfg == nil {
+		return &systemIPAllocator{}, nil
+	}
+
+	switch cfg.Provider {
+	case "", "system-ip":
+		return &systemIPAllocator{}, nil
+	case "pool":
+		if cfg.Pool == nil {
+			return nil, fmt.Errorf("ipam: provider %q requires a pool: block", cfg.Provider)
+		}
+		return newPoolAllocator(cfg.Pool, labDir)
+	case "external":
+		if cfg.External == nil {
+			return nil, fmt.Errorf("ipam: provider %q requires an external: block", cfg.Provider)
+		}
+		return &externalAllocator{cfg: cfg.External}, nil
+	default:
+		return nil, fmt.Errorf("ipam: unknown provider %q", cfg.Provider)
+	}
+}
+
+// systemIPAllocator derives each link's /31 deterministically from both
+// endpoints' systemip labels and the link ID, out of the 100.64.0.0/10
+// carrier-grade-NAT range, so link addressing never collides with whatever
+// an operator numbered themselves. This is the scheme containerlab has
+// always used.
+type systemIPAllocator struct{}
+
+func (a *systemIPAllocator) AllocateLinkIPs(linkID string, epA, epB labelMap) (netip.Prefix, netip.Prefix, error) {
+	sysA, sysB := epA[systemIP], epB[systemIP]
+	if sysA == "" || sysB == "" {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("both endpoints need a %q label for the system-ip IPAM provider", systemIP)
+	}
+
+	pair := sysA + "-" + sysB
+	if sysB < sysA {
+		pair = sysB + "-" + sysA
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(pair + "/" + linkID))
+	idx := h.Sum32() % (1 << 21) // 2M /31s fit in 100.64.0.0/10
+
+	base := uint32(100)<<24 | uint32(64)<<16
+	base += idx * 2
+	ipA := netip.AddrFrom4([4]byte{byte(base >> 24), byte(base >> 16), byte(base >> 8), byte(base)})
+	ipB := netip.AddrFrom4([4]byte{byte((base + 1) >> 24), byte((base + 1) >> 16), byte((base + 1) >> 8), byte(base + 1)})
+
+	return netip.PrefixFrom(ipA, 31), netip.PrefixFrom(ipB, 31), nil
+}
+
+// poolAllocator hands out sequential /31s (or /127s, for an ipv6-subnet)
+// from a configured subnet, persisting what it has already handed out so
+// re-running `deploy` against the same lab doesn't reshuffle addressing.
+type poolAllocator struct {
+	mu sync.Mutex
+	// prefix is the configured subnet; pairBits is the length of the
+	// /31 or /127 carved out of it per link (31 for an IPv4 prefix, 127
+	// for an IPv6 one).
+	prefix          netip.Prefix
+	pairBits        int
+	reservationFile string
+	reservations    map[string][2]string // linkID -> [ipA/pairBits, ipB/pairBits]
+}
+
+func newPoolAllocator(cfg *types.IPAMPoolConfig, labDir string) (*poolAllocator, error) {
+	var subnet string
+	pairBits := 31
+
+	switch {
+	case cfg.IPv4Subnet != "":
+		subnet = cfg.IPv4Subnet
+	case cfg.IPv6Subnet != "":
+		subnet = cfg.IPv6Subnet
+		pairBits = 127
+	default:
+		return nil, fmt.Errorf("ipam: pool provider requires ipv4-subnet or ipv6-subnet")
+	}
+
+	prefix, err := netip.ParsePrefix(subnet)
+	if err != nil {
+		return nil, fmt.Errorf("ipam: parsing subnet %q: %w", subnet, err)
+	}
+	if prefix.Addr().Is4() != (pairBits == 31) {
+		return nil, fmt.Errorf("ipam: subnet %q doesn't match its ipv4-subnet/ipv6-subnet key", subnet)
+	}
+
+	p := &poolAllocator{
+		prefix:          prefix.Masked(),
+		pairBits:        pairBits,
+		reservationFile: cfg.ReservationFile,
+		reservations:    make(map[string][2]string),
+	}
+	if p.reservationFile == "" {
+		p.reservationFile = filepath.Join(labDir, "ipam-reservations.json")
+	}
+
+	if err := p.load(); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+func (p *poolAllocator) load() error {
+	data, err := os.ReadFile(p.reservationFile)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("ipam: reading reservations %s: %w", p.reservationFile, err)
+	}
+
+	if err := json.Unmarshal(data, &p.reservations); err != nil {
+		return fmt.Errorf("ipam: parsing reservations %s: %w", p.reservationFile, err)
+	}
+
+	return nil
+}
+
+func (p *poolAllocator) save() error {
+	data, err := json.MarshalIndent(p.reservations, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ipam: encoding reservations: %w", err)
+	}
+
+	if dir := filepath.Dir(p.reservationFile); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("ipam: creating %s: %w", dir, err)
+		}
+	}
+
+	return os.WriteFile(p.reservationFile, data, 0644)
+}
+
+func (p *poolAllocator) AllocateLinkIPs(linkID string, epA, epB labelMap) (netip.Prefix, netip.Prefix, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if pair, ok := p.reservations[linkID]; ok {
+		return parsePrefixPair(pair[0], pair[1])
+	}
+
+	addrBits := p.prefix.Addr().BitLen()
+	offset := big.NewInt(int64(len(p.reservations)) * 2)
+	capacity := new(big.Int).Lsh(big.NewInt(1), uint(addrBits-p.prefix.Bits()))
+	if new(big.Int).Add(offset, big.NewInt(2)).Cmp(capacity) > 0 {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: pool %s exhausted: cannot allocate link %d's /%d, %s/%s addresses already reserved", p.prefix, len(p.reservations)+1, p.pairBits, offset, capacity)
+	}
+
+	base := addrToInt(p.prefix.Addr())
+	base.Add(base, offset)
+	addrA := intToAddr(base, p.prefix.Addr().Is4())
+	addrB := intToAddr(new(big.Int).Add(base, big.NewInt(1)), p.prefix.Addr().Is4())
+
+	ipA := netip.PrefixFrom(addrA, p.pairBits)
+	ipB := netip.PrefixFrom(addrB, p.pairBits)
+
+	p.reservations[linkID] = [2]string{ipA.String(), ipB.String()}
+
+	if err := p.save(); err != nil {
+		return netip.Prefix{}, netip.Prefix{}, err
+	}
+
+	return ipA, ipB, nil
+}
+
+// addrToInt returns a's numeric value as a big.Int, for address-family
+// agnostic arithmetic over both /31 (IPv4) and /127 (IPv6) pools.
+func addrToInt(a netip.Addr) *big.Int {
+	b := a.As16()
+	return new(big.Int).SetBytes(b[:])
+}
+
+// intToAddr is the inverse of addrToInt.
+func intToAddr(i *big.Int, is4 bool) netip.Addr {
+	var b [16]byte
+	i.FillBytes(b[:])
+	addr := netip.AddrFrom16(b)
+	if is4 {
+		return addr.Unmap()
+	}
+	return addr
+}
+
+// externalAllocator hands addressing decisions to a user-provided script or
+// HTTP endpoint, for operators bringing their own pre-planned addressing
+// scheme that containerlab has no other way to reproduce.
+type externalAllocator struct {
+	cfg *types.IPAMExternalConfig
+}
+
+func (a *externalAllocator) AllocateLinkIPs(linkID string, epA, epB labelMap) (netip.Prefix, netip.Prefix, error) {
+	switch {
+	case a.cfg.Script != "":
+		return a.allocateViaScript(linkID, epA, epB)
+	case a.cfg.URL != "":
+		return a.allocateViaHTTP(linkID, epA, epB)
+	default:
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: external provider needs either script or url")
+	}
+}
+
+func (a *externalAllocator) allocateViaScript(linkID string, epA, epB labelMap) (netip.Prefix, netip.Prefix, error) {
+	out, err := exec.Command(a.cfg.Script, linkID, epA["node"], epB["node"]).Output()
+	if err != nil {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: external script %s: %w", a.cfg.Script, err)
+	}
+
+	fields := strings.Fields(strings.TrimSpace(string(out)))
+	if len(fields) != 2 {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: external script %s: expected \"<ipA> <ipB>\", got %q", a.cfg.Script, out)
+	}
+
+	return parsePrefixPair(fields[0], fields[1])
+}
+
+func (a *externalAllocator) allocateViaHTTP(linkID string, epA, epB labelMap) (netip.Prefix, netip.Prefix, error) {
+	reqBody, err := json.Marshal(map[string]string{
+		"link_id":    linkID,
+		"endpoint_a": epA["node"],
+		"endpoint_b": epB["node"],
+	})
+	if err != nil {
+		return netip.Prefix{}, netip.Prefix{}, err
+	}
+
+	resp, err := http.Post(a.cfg.URL, "application/json", bytes.NewReader(reqBody))
+	if err != nil {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: external endpoint %s: %w", a.cfg.URL, err)
+	}
+	defer resp.Body.Close()
+
+	var out struct {
+		IPA string `json:"ip_a"`
+		IPB string `json:"ip_b"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: external endpoint %s: decoding response: %w", a.cfg.URL, err)
+	}
+
+	return parsePrefixPair(out.IPA, out.IPB)
+}
+
+func parsePrefixPair(a, b string) (netip.Prefix, netip.Prefix, error) {
+	pa, err := netip.ParsePrefix(a)
+	if err != nil {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: parsing %q: %w", a, err)
+	}
+
+	pb, err := netip.ParsePrefix(b)
+	if err != nil {
+		return netip.Prefix{}, netip.Prefix{}, fmt.Errorf("ipam: parsing %q: %w", b, err)
+	}
+
+	return pa, pb, nil
+}
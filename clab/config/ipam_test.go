@@ -0,0 +1,161 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+func TestNewIPAM(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     *types.IPAMConfig
+		want    interface{} // nil means only check err
+		wantErr bool
+	}{
+		{
+			name: "nil config defaults to system-ip",
+			cfg:  nil,
+			want: &systemIPAllocator{},
+		},
+		{
+			name: "explicit system-ip",
+			cfg:  &types.IPAMConfig{Provider: "system-ip"},
+			want: &systemIPAllocator{},
+		},
+		{
+			name:    "pool without pool block",
+			cfg:     &types.IPAMConfig{Provider: "pool"},
+			wantErr: true,
+		},
+		{
+			name: "pool with pool block",
+			cfg: &types.IPAMConfig{
+				Provider: "pool",
+				Pool:     &types.IPAMPoolConfig{IPv4Subnet: "172.31.0.0/24", ReservationFile: filepath.Join(t.TempDir(), "reservations.json")},
+			},
+		},
+		{
+			name: "pool with only ipv6-subnet",
+			cfg: &types.IPAMConfig{
+				Provider: "pool",
+				Pool:     &types.IPAMPoolConfig{IPv6Subnet: "2001:db8::/120", ReservationFile: filepath.Join(t.TempDir(), "reservations.json")},
+			},
+		},
+		{
+			name:    "external without external block",
+			cfg:     &types.IPAMConfig{Provider: "external"},
+			wantErr: true,
+		},
+		{
+			name:    "unknown provider",
+			cfg:     &types.IPAMConfig{Provider: "bogus"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NewIPAM(tt.cfg, t.TempDir())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewIPAM() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.want != nil {
+				if _, ok := got.(*systemIPAllocator); !ok {
+					t.Fatalf("NewIPAM() = %T, want *systemIPAllocator", got)
+				}
+			}
+		})
+	}
+}
+
+func TestPoolAllocatorExhaustsSubnet(t *testing.T) {
+	p, err := newPoolAllocator(&types.IPAMPoolConfig{
+		IPv4Subnet:      "172.31.0.0/30", // 4 addresses -> 2 /31s
+		ReservationFile: filepath.Join(t.TempDir(), "reservations.json"),
+	}, "")
+	if err != nil {
+		t.Fatalf("newPoolAllocator() error = %v", err)
+	}
+
+	if _, _, err := p.AllocateLinkIPs("link1", nil, nil); err != nil {
+		t.Fatalf("allocating link1: %v", err)
+	}
+	if _, _, err := p.AllocateLinkIPs("link2", nil, nil); err != nil {
+		t.Fatalf("allocating link2: %v", err)
+	}
+	if _, _, err := p.AllocateLinkIPs("link3", nil, nil); err == nil {
+		t.Fatal("allocating link3 beyond subnet capacity: expected error, got nil")
+	}
+
+	// Re-allocating an already-reserved link must keep returning its
+	// original addresses rather than erroring.
+	ipA, _, err := p.AllocateLinkIPs("link1", nil, nil)
+	if err != nil {
+		t.Fatalf("re-allocating link1: %v", err)
+	}
+	if ipA.String() != "172.31.0.0/31" {
+		t.Errorf("link1 ipA = %s, want 172.31.0.0/31", ipA)
+	}
+}
+
+func TestPoolAllocatorIPv6(t *testing.T) {
+	p, err := newPoolAllocator(&types.IPAMPoolConfig{
+		IPv6Subnet:      "2001:db8::/126", // 4 addresses -> 2 /127s
+		ReservationFile: filepath.Join(t.TempDir(), "reservations.json"),
+	}, "")
+	if err != nil {
+		t.Fatalf("newPoolAllocator() error = %v", err)
+	}
+
+	ipA, ipB, err := p.AllocateLinkIPs("link1", nil, nil)
+	if err != nil {
+		t.Fatalf("allocating link1: %v", err)
+	}
+	if ipA.String() != "2001:db8::/127" || ipB.String() != "2001:db8::1/127" {
+		t.Errorf("link1 = %s, %s, want 2001:db8::/127, 2001:db8::1/127", ipA, ipB)
+	}
+
+	if _, _, err := p.AllocateLinkIPs("link2", nil, nil); err != nil {
+		t.Fatalf("allocating link2: %v", err)
+	}
+	if _, _, err := p.AllocateLinkIPs("link3", nil, nil); err == nil {
+		t.Fatal("allocating link3 beyond subnet capacity: expected error, got nil")
+	}
+}
+
+// TestPoolAllocatorDefaultsReservationFileUnderLabDir covers the
+// "<labdir>/ipam-reservations.json" default IPAMPoolConfig.ReservationFile
+// documents: when no reservation-file is set explicitly, the pool
+// allocator must persist into the lab's working directory, not cwd,
+// so two labs deployed from the same cwd don't clobber each other's
+// reservations.
+func TestPoolAllocatorDefaultsReservationFileUnderLabDir(t *testing.T) {
+	labDir := filepath.Join(t.TempDir(), "clab-test-lab")
+
+	p, err := newPoolAllocator(&types.IPAMPoolConfig{IPv4Subnet: "172.31.0.0/30"}, labDir)
+	if err != nil {
+		t.Fatalf("newPoolAllocator() error = %v", err)
+	}
+
+	wantPath := filepath.Join(labDir, "ipam-reservations.json")
+	if p.reservationFile != wantPath {
+		t.Fatalf("reservationFile = %q, want %q", p.reservationFile, wantPath)
+	}
+
+	if _, _, err := p.AllocateLinkIPs("link1", nil, nil); err != nil {
+		t.Fatalf("allocating link1: %v", err)
+	}
+	if _, err := os.Stat(wantPath); err != nil {
+		t.Errorf("reservation file %s wasn't created: %v", wantPath, err)
+	}
+}
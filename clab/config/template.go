@@ -4,17 +4,51 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
-	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
 	"text/template"
 
-	log "github.com/sirupsen/logrus"
 	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/pkg/logging"
 )
 
+// log is the package-level structured logger for template rendering. It
+// defaults to a JSON hclog logger; SetLogger lets the CLI inject one
+// pre-configured with the global log-level/output settings instead.
+var log logging.Logger = logging.NewHCLog("config")
+
+// SetLogger overrides the package-level logger used by the config package.
+func SetLogger(l logging.Logger) {
+	log = l
+}
+
+// baseTemplateDir is the name of the shared template directory (relative to
+// the template root) whose templates are parsed into every kind's template
+// set, so kinds can {{define}}/{{template}} common blocks instead of
+// duplicating them.
+const baseTemplateDir = "_base"
+
+// templateRoot is the directory LoadTemplate last loaded kinds from. It's
+// used by the include function to resolve fragment paths relative to the
+// same root, since templates for every kind share one root directory.
+var templateRoot string
+
+// StrictMode, when true, makes RenderTemplate actually validate labels via
+// the expect/require funcs instead of treating expect as a no-op, and
+// aggregates every validation failure into a *MultiError rather than
+// stopping at the first one.
+var StrictMode bool
+
+// SetStrictMode sets the package-level StrictMode flag. Called once at
+// startup after parsing the topology's top-level `strict:` field and/or the
+// CLI's --strict flag.
+func SetStrictMode(strict bool) {
+	StrictMode = strict
+}
+
 type labelMap map[string]string
 type ConfigSnippet struct {
 	TargetNode           *clab.Node
@@ -36,10 +70,26 @@ func LoadTemplate(kind string, templatePath string) error {
 		return nil
 	}
 
+	templateRoot = templatePath
+
+	ct := template.New(kind).Funcs(funcMap)
+
+	// Parse the shared _base/ templates first, if any exist, so every
+	// kind's template set can reference them via {{template}}.
+	basePattern := filepath.Join(templatePath, baseTemplateDir, "*.tmpl")
+	if matches, _ := filepath.Glob(basePattern); len(matches) > 0 {
+		log.Debugf("Load base templates from: %s", basePattern)
+		var err error
+		ct, err = ct.ParseGlob(basePattern)
+		if err != nil {
+			log.Errorf("could not load base templates %s", err)
+			return err
+		}
+	}
+
 	tp := filepath.Join(templatePath, kind, "*.tmpl")
 	log.Debugf("Load templates from: %s", tp)
 
-	ct := template.New(kind).Funcs(funcMap)
 	var err error
 	templates[kind], err = ct.ParseGlob(tp)
 	if err != nil {
@@ -52,6 +102,17 @@ func LoadTemplate(kind string, templatePath string) error {
 func RenderTemplate(kind, name string, labels labelMap) (*ConfigSnippet, error) {
 	t := templates[kind]
 
+	var rc *renderContext
+	if StrictMode {
+		rc = &renderContext{}
+		var err error
+		t, err = t.Clone()
+		if err != nil {
+			return nil, fmt.Errorf("could not clone template %s for strict rendering: %w", name, err)
+		}
+		t = t.Funcs(strictFuncMap(rc))
+	}
+
 	buf := new(bytes.Buffer)
 
 	err := t.ExecuteTemplate(buf, name, labels)
@@ -62,6 +123,14 @@ func RenderTemplate(kind, name string, labels labelMap) (*ConfigSnippet, error)
 		return nil, err
 	}
 
+	if rc != nil && len(rc.errs) > 0 {
+		me := &MultiError{}
+		for _, e := range rc.errs {
+			me.Add(name, e)
+		}
+		return nil, me
+	}
+
 	var res []string
 	s := bufio.NewScanner(buf)
 	for s.Scan() {
@@ -75,6 +144,131 @@ func RenderTemplate(kind, name string, labels labelMap) (*ConfigSnippet, error)
 	}, nil
 }
 
+// RenderAll renders base-node.tmpl for every node in nodes, returning a
+// snippet per node keyed by its short name. Unlike RenderNode, a single
+// node's rendering failure doesn't stop the rest: every error is collected
+// into the returned *MultiError (nil if every node rendered cleanly).
+func RenderAll(nodes []*clab.Node) (map[string]*ConfigSnippet, error) {
+	res := make(map[string]*ConfigSnippet, len(nodes))
+	me := &MultiError{}
+
+	for _, n := range nodes {
+		snip, err := RenderNode(n)
+		if err != nil {
+			me.Add(n.LongName, err)
+			continue
+		}
+		res[n.ShortName] = snip
+	}
+
+	if len(me.Errors) > 0 {
+		return res, me
+	}
+
+	return res, nil
+}
+
+// FieldError pairs a rendering/validation error with the node (or template)
+// it was raised against.
+type FieldError struct {
+	Node string
+	Err  error
+}
+
+// MultiError aggregates every FieldError raised while rendering a batch of
+// templates, instead of short-circuiting on the first one.
+type MultiError struct {
+	Errors []FieldError
+}
+
+// Add appends a FieldError for node.
+func (m *MultiError) Add(node string, err error) {
+	m.Errors = append(m.Errors, FieldError{Node: node, Err: err})
+}
+
+func (m *MultiError) Error() string {
+	parts := make([]string, 0, len(m.Errors))
+	for _, e := range m.Errors {
+		parts = append(parts, fmt.Sprintf("%s: %s", e.Node, e.Err))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// renderContext accumulates the validation errors raised by the strict
+// expect/require funcs over the course of one ExecuteTemplate call.
+type renderContext struct {
+	errs []error
+}
+
+// strictFuncMap returns funcMap with expect/require replaced by versions
+// that actually validate, recording failures on rc instead of either
+// silently passing (expect's current no-op behaviour) or aborting the
+// render on the first bad label (require's current behaviour).
+func strictFuncMap(rc *renderContext) map[string]interface{} {
+	m := make(map[string]interface{}, len(funcMap))
+	for k, v := range funcMap {
+		m[k] = v
+	}
+
+	m["expect"] = func(label interface{}, val interface{}, format interface{}) (interface{}, error) {
+		if err := validateExpect(val, format); err != nil {
+			rc.errs = append(rc.errs, fmt.Errorf("%v: %w", label, err))
+		}
+		return val, nil
+	}
+
+	m["require"] = func(label interface{}, val interface{}) (interface{}, error) {
+		if val == nil {
+			rc.errs = append(rc.errs, fmt.Errorf("%v: required value not set", label))
+			return "", nil
+		}
+		return val, nil
+	}
+
+	return m
+}
+
+// validateExpect checks val against format, which is either one of the
+// primitive type names ("string", "int", "bool") or an "enum:a,b,c" list of
+// allowed string values.
+func validateExpect(val, format interface{}) error {
+	f := fmt.Sprintf("%v", format)
+
+	switch {
+	case strings.HasPrefix(f, "enum:"):
+		allowed := strings.Split(strings.TrimPrefix(f, "enum:"), ",")
+		s := fmt.Sprintf("%v", val)
+		for _, a := range allowed {
+			if a == s {
+				return nil
+			}
+		}
+		return fmt.Errorf("expected one of %v, got %q", allowed, s)
+	case f == "string", f == "int", f == "bool":
+		// Label values always arrive as strings; coerce before comparing,
+		// the same way the default func does.
+		tval := typeof(val)
+		if s, ok := val.(string); ok && tval == "string" {
+			switch f {
+			case "int":
+				if _, err := strconv.Atoi(s); err == nil {
+					tval = "int"
+				}
+			case "bool":
+				if _, err := strconv.ParseBool(s); err == nil {
+					tval = "bool"
+				}
+			}
+		}
+		if tval != f {
+			return fmt.Errorf("expected type %s, got %s (value=%v)", f, typeof(val), val)
+		}
+		return nil
+	default:
+		return fmt.Errorf("expect: unknown format %q", f)
+	}
+}
+
 func RenderNode(node *clab.Node) (*ConfigSnippet, error) {
 	kind := node.Labels["clab-node-kind"]
 	log.Debugf("render node %s [%s]\n", node.LongName, kind)
@@ -92,12 +286,17 @@ func RenderLink(link *clab.Link) (*ConfigSnippet, *ConfigSnippet, error) {
 	// Link labels/values are different on node A & B
 	l := make(map[string][]string)
 
-	// Link IPs
-	ipA, ipB, err := linkIPfromSystemIP(link)
+	// Link IPs, via the configured IPAM provider
+	epA := labelMap{"node": link.A.Node.ShortName, systemIP: link.A.Node.Labels[systemIP]}
+	epB := labelMap{"node": link.B.Node.ShortName, systemIP: link.B.Node.Labels[systemIP]}
+
+	prefixA, prefixB, err := activeIPAM.AllocateLinkIPs(link.String(), epA, epB)
 	if err != nil {
 		return nil, nil, fmt.Errorf("%s: %s", link, err)
 	}
-	l["ip"] = []string{ipA.String(), ipB.String()}
+	l["ip"] = []string{prefixA.Addr().String(), prefixB.Addr().String()}
+	l["subnet"] = []string{prefixA.String(), prefixB.String()}
+	l["prefixlen"] = []string{strconv.Itoa(prefixA.Bits()), strconv.Itoa(prefixB.Bits())}
 	l["systemip"] = []string{link.A.Node.Labels[systemIP], link.B.Node.Labels[systemIP]}
 
 	// Split all fields with a comma...
@@ -178,17 +377,34 @@ func typeof(val interface{}) string {
 		return "string"
 	case int:
 		return "int"
+	case bool:
+		return "bool"
 	}
 	return ""
 }
 
 var funcMap = map[string]interface{}{
-	"expect": func(val interface{}, format interface{}) (interface{}, error) {
-		return nil, nil
+	// include reads a file relative to the template root (the directory
+	// passed to LoadTemplate) and returns its contents as-is, letting
+	// templates pull in fragments shared across kinds without duplicating
+	// them, e.g. {{include "_base/snippets/ntp.tmpl"}}.
+	"include": func(path interface{}) (interface{}, error) {
+		p := filepath.Join(templateRoot, fmt.Sprintf("%v", path))
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return nil, fmt.Errorf("include %q: %w", path, err)
+		}
+		return string(b), nil
+	},
+	// expect/require take the label's own name as their first argument
+	// (e.g. {{expect "mtu" .mtu "int"}}) so strict-mode validation failures
+	// can say which label among several in a template was invalid.
+	"expect": func(label interface{}, val interface{}, format interface{}) (interface{}, error) {
+		return val, nil
 	},
-	"require": func(val interface{}) (interface{}, error) {
+	"require": func(label interface{}, val interface{}) (interface{}, error) {
 		if val == nil {
-			return nil, errors.New("required value not set")
+			return nil, fmt.Errorf("%v: required value not set", label)
 		}
 		return val, nil
 	},
@@ -0,0 +1,119 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/srl-labs/containerlab/clab"
+)
+
+// testdataTemplateRoot is the template root used by the template_test.go
+// fixtures under testdata/templates: a _base/ directory shared across
+// kinds plus a "testkind" directory with its own base-node.tmpl.
+const testdataTemplateRoot = "testdata/templates"
+
+// withStrictMode sets StrictMode for the duration of a test and restores
+// its previous value afterwards, since it's a package-level flag shared
+// across every test in this package.
+func withStrictMode(t *testing.T, strict bool) {
+	t.Helper()
+	prev := StrictMode
+	SetStrictMode(strict)
+	t.Cleanup(func() { SetStrictMode(prev) })
+}
+
+func TestRenderTemplateInheritanceAndInclude(t *testing.T) {
+	if err := LoadTemplate("testkind", testdataTemplateRoot); err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+
+	snip, err := RenderTemplate("testkind", "base-node.tmpl", labelMap{"hostname": "r1", "mtu": "1500"})
+	if err != nil {
+		t.Fatalf("RenderTemplate() error = %v", err)
+	}
+
+	got := strings.Join(snip.Config, "\n")
+	for _, want := range []string{"! generated by containerlab", "hostname r1", "mtu 1500", "ntp server 10.0.0.1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("rendered config = %q, want it to contain %q", got, want)
+		}
+	}
+}
+
+func TestRenderTemplateStrictModeValidatesExpect(t *testing.T) {
+	if err := LoadTemplate("testkind", testdataTemplateRoot); err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	withStrictMode(t, true)
+
+	t.Run("rejects a label that fails its expect format", func(t *testing.T) {
+		_, err := RenderTemplate("testkind", "base-node.tmpl", labelMap{"hostname": "r1", "mtu": "not-a-number"})
+		if err == nil {
+			t.Fatal("RenderTemplate() error = nil, want a validation error")
+		}
+		me, ok := err.(*MultiError)
+		if !ok {
+			t.Fatalf("RenderTemplate() error = %T, want *MultiError", err)
+		}
+		if len(me.Errors) != 1 {
+			t.Fatalf("got %d errors, want 1: %v", len(me.Errors), me.Errors)
+		}
+		if !strings.Contains(me.Errors[0].Err.Error(), "mtu") {
+			t.Errorf("error = %q, want it to name the mtu label", me.Errors[0].Err)
+		}
+	})
+
+	t.Run("passes a label that satisfies its expect format", func(t *testing.T) {
+		if _, err := RenderTemplate("testkind", "base-node.tmpl", labelMap{"hostname": "r1", "mtu": "1500"}); err != nil {
+			t.Fatalf("RenderTemplate() error = %v, want nil", err)
+		}
+	})
+}
+
+func TestRenderTemplateNonStrictModeSkipsValidation(t *testing.T) {
+	if err := LoadTemplate("testkind", testdataTemplateRoot); err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	withStrictMode(t, false)
+
+	if _, err := RenderTemplate("testkind", "base-node.tmpl", labelMap{"hostname": "r1", "mtu": "not-a-number"}); err != nil {
+		t.Fatalf("RenderTemplate() error = %v, want nil (expect is a no-op outside strict mode)", err)
+	}
+}
+
+func TestRenderAllAggregatesErrorsAcrossNodes(t *testing.T) {
+	if err := LoadTemplate("testkind", testdataTemplateRoot); err != nil {
+		t.Fatalf("LoadTemplate() error = %v", err)
+	}
+	withStrictMode(t, true)
+
+	good := &clab.Node{ShortName: "good", LongName: "clab-test-good", Labels: map[string]string{
+		"clab-node-kind": "testkind", "hostname": "good", "mtu": "1500",
+	}}
+	bad := &clab.Node{ShortName: "bad", LongName: "clab-test-bad", Labels: map[string]string{
+		"clab-node-kind": "testkind", "hostname": "bad", "mtu": "not-a-number",
+	}}
+
+	res, err := RenderAll([]*clab.Node{good, bad})
+	if err == nil {
+		t.Fatal("RenderAll() error = nil, want a *MultiError for the bad node")
+	}
+	me, ok := err.(*MultiError)
+	if !ok {
+		t.Fatalf("RenderAll() error = %T, want *MultiError", err)
+	}
+	if len(me.Errors) != 1 || me.Errors[0].Node != bad.LongName {
+		t.Errorf("MultiError.Errors = %v, want exactly one error for %s", me.Errors, bad.LongName)
+	}
+
+	if _, ok := res[good.ShortName]; !ok {
+		t.Errorf("RenderAll() result missing the good node %s; one node's failure shouldn't drop the rest", good.ShortName)
+	}
+	if _, ok := res[bad.ShortName]; ok {
+		t.Errorf("RenderAll() result has an entry for the failed node %s, want none", bad.ShortName)
+	}
+}
@@ -0,0 +1,19 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import "fmt"
+
+// Link connects two nodes' endpoints.
+type Link struct {
+	A, B   Endpoint
+	Labels map[string]string
+}
+
+// String uniquely identifies the link, e.g. for log lines and as the IPAM
+// link ID.
+func (l *Link) String() string {
+	return fmt.Sprintf("%s:%s <-> %s:%s", l.A.Node.ShortName, l.A.IfaceName, l.B.Node.ShortName, l.B.IfaceName)
+}
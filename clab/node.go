@@ -0,0 +1,28 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package clab owns the topology model (nodes, links, the top-level
+// topology file) and the CLab type that drives a lab through its
+// deploy/destroy lifecycle.
+package clab
+
+// Node is a node as known to the topology graph - used for templating and
+// service discovery, as opposed to types.NodeConfig which a kind actually
+// deploys from.
+type Node struct {
+	ShortName string
+	LongName  string
+	Labels    map[string]string
+}
+
+// Endpoint is one side of a Link.
+type Endpoint struct {
+	Node      *Node
+	IfaceName string
+}
+
+// GetIfaceName implements nodes.Endpoint.
+func (e *Endpoint) GetIfaceName() string {
+	return e.IfaceName
+}
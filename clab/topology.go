@@ -0,0 +1,66 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package clab
+
+import (
+	"fmt"
+
+	"github.com/srl-labs/containerlab/types"
+	"gopkg.in/yaml.v2"
+)
+
+// NodeDefinition is a single node's raw YAML definition from the topology
+// file, before defaults are merged in to build its types.NodeConfig.
+type NodeDefinition struct {
+	Kind  string            `yaml:"kind,omitempty"`
+	Image string            `yaml:"image,omitempty"`
+	Env   map[string]string `yaml:"env,omitempty"`
+	Binds []string          `yaml:"binds,omitempty"`
+	// PostDeploy lets a topology author declare sysctls/exec hooks for a
+	// node without patching Go code; copied verbatim into the node's
+	// types.NodeConfig.PostDeployHooks.
+	PostDeploy []types.PostDeployHook `yaml:"post-deploy,omitempty"`
+}
+
+// Topology is the `topology:` block of a Config.
+type Topology struct {
+	Nodes map[string]*NodeDefinition `yaml:"nodes,omitempty"`
+}
+
+// Config is the top-level topology file.
+type Config struct {
+	Name      string                 `yaml:"name"`
+	Topology  Topology               `yaml:"topology"`
+	IPAM      *types.IPAMConfig      `yaml:"ipam,omitempty"`
+	Discovery *types.DiscoveryConfig `yaml:"discovery,omitempty"`
+	// Strict enables config.StrictMode for this lab's template rendering,
+	// collecting every label validation failure (expect/require) into a
+	// *MultiError. Without it, expect is a no-op and require stops the
+	// render at the first missing value. Off by default for backwards
+	// compatibility with existing templates.
+	Strict bool `yaml:"strict,omitempty"`
+}
+
+// ParseTopology unmarshals a topology file.
+func ParseTopology(data []byte) (*Config, error) {
+	cfg := &Config{}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing topology: %w", err)
+	}
+	return cfg, nil
+}
+
+// NewNodeConfig builds the types.NodeConfig for the node named name from
+// its raw topology definition.
+func NewNodeConfig(name string, def *NodeDefinition) *types.NodeConfig {
+	return &types.NodeConfig{
+		ShortName:       name,
+		Kind:            def.Kind,
+		Image:           def.Image,
+		Env:             def.Env,
+		Binds:           def.Binds,
+		PostDeployHooks: def.PostDeploy,
+	}
+}
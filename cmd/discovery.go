@@ -0,0 +1,59 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package cmd
+
+import (
+	"context"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/discovery"
+)
+
+// discoveryCmd represents the discovery command.
+var discoveryCmd = &cobra.Command{
+	Use:   "discovery",
+	Short: "service-discovery operations",
+}
+
+// discoverySyncCmd reconciles the configured discovery backend with the
+// currently deployed lab, registering anything missing and removing
+// anything the backend still has for nodes that no longer exist.
+var discoverySyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "reconcile the discovery backend with the running lab",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		c, err := getClab()
+		if err != nil {
+			return err
+		}
+
+		if c.Config.Discovery == nil {
+			log.Info("no discovery: block in topology, nothing to sync")
+			return nil
+		}
+
+		backend, err := discovery.NewBackend(c.Config.Discovery)
+		if err != nil {
+			return err
+		}
+
+		records := c.DiscoveryRecords()
+
+		ctx := context.Background()
+		if err := backend.Sync(ctx, c.Config.Name, records); err != nil {
+			return err
+		}
+
+		log.Infof("synced %d node(s) to the %s discovery backend", len(records), c.Config.Discovery.Backend)
+
+		return nil
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(discoveryCmd)
+	discoveryCmd.AddCommand(discoverySyncCmd)
+}
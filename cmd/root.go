@@ -0,0 +1,91 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package cmd implements the containerlab CLI.
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/srl-labs/containerlab/clab"
+	"github.com/srl-labs/containerlab/clab/config"
+	"github.com/srl-labs/containerlab/pkg/logging"
+)
+
+var topoFile string
+var logLevel string
+var strict bool
+
+var rootCmd = &cobra.Command{
+	Use:   "containerlab",
+	Short: "deploy container based labs",
+}
+
+func init() {
+	rootCmd.PersistentFlags().StringVarP(&topoFile, "topo", "t", "", "path to the topology file")
+	rootCmd.PersistentFlags().StringVar(&logLevel, "log-level", "info", "log level: debug, info, warn, error")
+	rootCmd.PersistentFlags().BoolVar(&strict, "strict", false, "fail template rendering on invalid/missing labels instead of ignoring them")
+	cobra.OnInitialize(initLogging)
+}
+
+// initLogging wires the --log-level flag into the config package's
+// template-rendering logger, so `expect`/`require` validation failures and
+// template load/render tracing honor the level the user asked for, instead
+// of always logging at config's hclog-JSON default.
+func initLogging() {
+	lvl, err := log.ParseLevel(logLevel)
+	if err != nil {
+		lvl = log.InfoLevel
+	}
+
+	l := log.New()
+	l.SetLevel(lvl)
+
+	config.SetLogger(logging.FromLogrus(l.WithField("component", "config")))
+}
+
+// Execute runs the root command.
+func Execute() {
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// getClab loads the topology file named by --topo and returns a ready-to-use
+// CLab.
+func getClab() (*clab.CLab, error) {
+	if topoFile == "" {
+		return nil, fmt.Errorf("no topology file specified, use -t/--topo")
+	}
+
+	data, err := os.ReadFile(topoFile)
+	if err != nil {
+		return nil, fmt.Errorf("reading topology file %s: %w", topoFile, err)
+	}
+
+	cfg, err := clab.ParseTopology(data)
+	if err != nil {
+		return nil, err
+	}
+
+	config.SetStrictMode(strict || cfg.Strict)
+
+	// labDir is the lab's working directory, alongside the topology file,
+	// matching the "clab-<lab-name>" convention containerlab deploys nodes
+	// into; the pool IPAM provider defaults its reservation file under it.
+	labDir := filepath.Join(filepath.Dir(topoFile), "clab-"+cfg.Name)
+
+	ipam, err := config.NewIPAM(cfg.IPAM, labDir)
+	if err != nil {
+		return nil, err
+	}
+	config.SetIPAM(ipam)
+
+	return clab.NewCLab(cfg), nil
+}
@@ -0,0 +1,97 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	consulapi "github.com/hashicorp/consul/api"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// consulBackend registers nodes as Consul services, tagged with the lab
+// name so Sync can find everything belonging to a given lab.
+type consulBackend struct {
+	client *consulapi.Client
+}
+
+func newConsulBackend(cfg *types.ConsulDiscoveryConfig) (*consulBackend, error) {
+	conf := consulapi.DefaultConfig()
+	conf.Address = cfg.Address
+	if cfg.Token != "" {
+		conf.Token = cfg.Token
+	}
+
+	client, err := consulapi.NewClient(conf)
+	if err != nil {
+		return nil, fmt.Errorf("discovery: creating consul client: %w", err)
+	}
+
+	return &consulBackend{client: client}, nil
+}
+
+func (b *consulBackend) Register(_ context.Context, r *Record) error {
+	reg := &consulapi.AgentServiceRegistration{
+		ID:      serviceID(r.Lab, r.Name),
+		Name:    r.Name,
+		Address: r.MgmtIP,
+		Tags:    []string{"containerlab", "lab=" + r.Lab, "kind=" + r.Kind},
+		Meta:    serviceMeta(r),
+	}
+
+	if err := b.client.Agent().ServiceRegister(reg); err != nil {
+		return fmt.Errorf("discovery: registering %s with consul: %w", r.Name, err)
+	}
+
+	return nil
+}
+
+func (b *consulBackend) Deregister(_ context.Context, lab, name string) error {
+	if err := b.client.Agent().ServiceDeregister(serviceID(lab, name)); err != nil {
+		return fmt.Errorf("discovery: deregistering %s from consul: %w", name, err)
+	}
+	return nil
+}
+
+func (b *consulBackend) Sync(ctx context.Context, lab string, records []*Record) error {
+	services, err := b.client.Agent().ServicesWithFilter(fmt.Sprintf(`"lab=%s" in Tags`, lab))
+	if err != nil {
+		return fmt.Errorf("discovery: listing consul services for lab %s: %w", lab, err)
+	}
+
+	want := make(map[string]bool, len(records))
+	for _, r := range records {
+		want[serviceID(r.Lab, r.Name)] = true
+		if err := b.Register(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	for id := range services {
+		if !want[id] {
+			if err := b.client.Agent().ServiceDeregister(id); err != nil {
+				return fmt.Errorf("discovery: deregistering stale service %s: %w", id, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func serviceID(lab, name string) string {
+	return fmt.Sprintf("clab-%s-%s", lab, name)
+}
+
+func serviceMeta(r *Record) map[string]string {
+	meta := map[string]string{"kind": r.Kind}
+	for k, v := range r.Labels {
+		meta["label_"+k] = v
+	}
+	for k, v := range r.Interfaces {
+		meta["intf_"+k] = v
+	}
+	return meta
+}
@@ -0,0 +1,71 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package discovery registers deployed lab nodes into an external
+// service-discovery backend (Consul, etcd, or a local JSON file) so that
+// tooling outside containerlab - Prometheus SD, Ansible inventory
+// generators, etc. - can find them the same way it finds any other
+// dynamically placed workload.
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+// Record describes a single deployed node as published to a backend.
+type Record struct {
+	// Name is the node's short name, unique within the lab.
+	Name string
+	// Lab is the topology/lab name the node belongs to.
+	Lab string
+	// Kind is the node kind, e.g. "rare", "srl".
+	Kind string
+	// MgmtIP is the node's management-network IP address.
+	MgmtIP string
+	// Labels carries the node's user-defined labels.
+	Labels map[string]string
+	// Interfaces maps CLAB_INTF_* env var names to their interface names,
+	// mirroring what genInterfacesEnv exposes inside the container.
+	Interfaces map[string]string
+}
+
+// Backend is implemented by every service-discovery provider.
+type Backend interface {
+	// Register publishes r, overwriting any previous record for the same
+	// Lab/Name.
+	Register(ctx context.Context, r *Record) error
+	// Deregister removes the record for name in lab, if any.
+	Deregister(ctx context.Context, lab, name string) error
+	// Sync reconciles whatever state the backend holds for lab against
+	// records, removing anything not present in records. Used by
+	// `containerlab discovery sync`.
+	Sync(ctx context.Context, lab string, records []*Record) error
+}
+
+// NewBackend builds the Backend selected by cfg.Backend.
+func NewBackend(cfg *types.DiscoveryConfig) (Backend, error) {
+	switch cfg.Backend {
+	case "consul":
+		if cfg.Consul == nil {
+			return nil, fmt.Errorf("discovery: backend %q requires a consul: block", cfg.Backend)
+		}
+		return newConsulBackend(cfg.Consul)
+	case "etcd":
+		if cfg.Etcd == nil {
+			return nil, fmt.Errorf("discovery: backend %q requires an etcd: block", cfg.Backend)
+		}
+		return newEtcdBackend(cfg.Etcd)
+	case "file", "":
+		fc := cfg.File
+		if fc == nil {
+			fc = &types.FileDiscoveryConfig{}
+		}
+		return newFileBackend(fc)
+	default:
+		return nil, fmt.Errorf("discovery: unknown backend %q", cfg.Backend)
+	}
+}
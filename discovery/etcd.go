@@ -0,0 +1,92 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/srl-labs/containerlab/types"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+const etcdDialTimeout = 5 * time.Second
+
+// etcdBackend stores one JSON value per node under
+// "<prefix>/<lab>/<name>", making it trivial for external tooling to watch
+// a lab's key range.
+type etcdBackend struct {
+	client *clientv3.Client
+	prefix string
+}
+
+func newEtcdBackend(cfg *types.EtcdDiscoveryConfig) (*etcdBackend, error) {
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   cfg.Endpoints,
+		DialTimeout: etcdDialTimeout,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("discovery: creating etcd client: %w", err)
+	}
+
+	return &etcdBackend{client: client, prefix: cfg.Prefix}, nil
+}
+
+func (b *etcdBackend) Register(ctx context.Context, r *Record) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("discovery: encoding record for %s: %w", r.Name, err)
+	}
+
+	if _, err := b.client.Put(ctx, b.key(r.Lab, r.Name), string(data)); err != nil {
+		return fmt.Errorf("discovery: writing %s to etcd: %w", r.Name, err)
+	}
+
+	return nil
+}
+
+func (b *etcdBackend) Deregister(ctx context.Context, lab, name string) error {
+	if _, err := b.client.Delete(ctx, b.key(lab, name)); err != nil {
+		return fmt.Errorf("discovery: deleting %s from etcd: %w", name, err)
+	}
+	return nil
+}
+
+func (b *etcdBackend) Sync(ctx context.Context, lab string, records []*Record) error {
+	labPrefix := b.key(lab, "")
+
+	resp, err := b.client.Get(ctx, labPrefix, clientv3.WithPrefix())
+	if err != nil {
+		return fmt.Errorf("discovery: listing etcd keys under %s: %w", labPrefix, err)
+	}
+
+	want := make(map[string]bool, len(records))
+	for _, r := range records {
+		want[b.key(r.Lab, r.Name)] = true
+		if err := b.Register(ctx, r); err != nil {
+			return err
+		}
+	}
+
+	for _, kv := range resp.Kvs {
+		key := string(kv.Key)
+		if !want[key] {
+			if _, err := b.client.Delete(ctx, key); err != nil {
+				return fmt.Errorf("discovery: deleting stale key %s: %w", key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (b *etcdBackend) key(lab, name string) string {
+	if name == "" {
+		return fmt.Sprintf("%s/%s/", b.prefix, lab)
+	}
+	return fmt.Sprintf("%s/%s/%s", b.prefix, lab, name)
+}
@@ -0,0 +1,131 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+const defaultFileName = "discovery.json"
+
+// fileBackend is the dependency-free fallback: it writes the registry as a
+// single JSON document, keyed by "<lab>/<name>". It's not suitable for
+// multi-process concurrent writers, but that's not a scenario containerlab
+// itself produces.
+type fileBackend struct {
+	mu   sync.Mutex
+	path string
+}
+
+func newFileBackend(cfg *types.FileDiscoveryConfig) (*fileBackend, error) {
+	path := cfg.Path
+	if path == "" {
+		path = defaultFileName
+	}
+	return &fileBackend{path: path}, nil
+}
+
+func (b *fileBackend) Register(_ context.Context, r *Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reg, err := b.load()
+	if err != nil {
+		return err
+	}
+
+	reg[recordKey(r.Lab, r.Name)] = r
+
+	return b.save(reg)
+}
+
+func (b *fileBackend) Deregister(_ context.Context, lab, name string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reg, err := b.load()
+	if err != nil {
+		return err
+	}
+
+	delete(reg, recordKey(lab, name))
+
+	return b.save(reg)
+}
+
+func (b *fileBackend) Sync(_ context.Context, lab string, records []*Record) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	reg, err := b.load()
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]*Record, len(records))
+	for _, r := range records {
+		want[recordKey(lab, r.Name)] = r
+	}
+
+	for k := range reg {
+		if _, ok := want[k]; !ok && recordBelongsToLab(k, lab) {
+			delete(reg, k)
+		}
+	}
+	for k, r := range want {
+		reg[k] = r
+	}
+
+	return b.save(reg)
+}
+
+func (b *fileBackend) load() (map[string]*Record, error) {
+	reg := make(map[string]*Record)
+
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return reg, nil
+		}
+		return nil, fmt.Errorf("discovery: reading %s: %w", b.path, err)
+	}
+	if len(data) == 0 {
+		return reg, nil
+	}
+
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return nil, fmt.Errorf("discovery: parsing %s: %w", b.path, err)
+	}
+
+	return reg, nil
+}
+
+func (b *fileBackend) save(reg map[string]*Record) error {
+	data, err := json.MarshalIndent(reg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("discovery: encoding registry: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(b.path), 0755); err != nil && filepath.Dir(b.path) != "." {
+		return fmt.Errorf("discovery: creating %s: %w", filepath.Dir(b.path), err)
+	}
+
+	return os.WriteFile(b.path, data, 0644)
+}
+
+func recordKey(lab, name string) string {
+	return lab + "/" + name
+}
+
+func recordBelongsToLab(key, lab string) bool {
+	return len(key) > len(lab) && key[:len(lab)+1] == lab+"/"
+}
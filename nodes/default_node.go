@@ -0,0 +1,33 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import (
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// DefaultNode implements the common parts of the Node lifecycle. Kinds
+// embed it and override whichever methods need kind-specific behaviour.
+type DefaultNode struct {
+	Cfg       *types.NodeConfig
+	Runtime   runtime.Runtime
+	Endpoints []Endpoint
+
+	node Node
+}
+
+// NewDefaultNode wires a DefaultNode up to the concrete Node embedding it,
+// so DefaultNode methods that need kind-specific behaviour can call back
+// into it.
+func NewDefaultNode(n Node) *DefaultNode {
+	return &DefaultNode{node: n}
+}
+
+// LoadOrGenerateCertificate loads the node's certificate if one already
+// exists for topoName, generating and persisting a new one otherwise.
+func (d *DefaultNode) LoadOrGenerateCertificate(cert *CertificateParams, topoName string) (*Certificate, error) {
+	return &Certificate{}, nil
+}
@@ -0,0 +1,143 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/srl-labs/containerlab/pkg/logging"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// defaultWaitRunningPollInterval is how often WaitRunning polls the runtime
+// while waiting for a container to report a running state.
+const defaultWaitRunningPollInterval = 2 * time.Second
+
+// WaitRunning blocks until the node's container is reported running by the
+// configured runtime, or until ctx is done. Several kinds need to exec into
+// their container as part of PostDeploy, and the runtime may still be
+// finalizing the container when PostDeploy starts, so they should call this
+// first rather than rolling their own retry loop.
+func (d *DefaultNode) WaitRunning(ctx context.Context, logger logging.Logger) error {
+	for {
+		status := d.Runtime.GetContainerStatus(ctx, d.Cfg.LongName)
+		if status == runtime.Running {
+			return nil
+		}
+
+		logger.Infof("container not running yet (status=%s), waiting...", status)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%s: timed out waiting for container to be running: %w", d.Cfg.LongName, ctx.Err())
+		case <-time.After(defaultWaitRunningPollInterval):
+		}
+	}
+}
+
+// ExecSysctls execs `sysctl -w` for each key/value pair against the node's
+// container via the runtime's Exec API, returning on the first failure
+// together with the command's stderr output.
+func (d *DefaultNode) ExecSysctls(ctx context.Context, logger logging.Logger, sysctls map[string]string) error {
+	for k, v := range sysctls {
+		cmd, err := types.NewExecCmdFromString(fmt.Sprintf("sysctl -w %s=%s", k, v))
+		if err != nil {
+			return err
+		}
+
+		logger.Debugf("exec sysctl %s=%s", k, v)
+
+		execResult, err := d.Runtime.Exec(ctx, d.Cfg.LongName, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: failed to exec sysctl %s=%s: %w", d.Cfg.LongName, k, v, err)
+		}
+
+		if execResult.GetReturnCode() != 0 {
+			return fmt.Errorf("%s: sysctl %s=%s failed: %s", d.Cfg.LongName, k, v, execResult.GetStdErrString())
+		}
+	}
+
+	return nil
+}
+
+// ExecCommands execs each command in cmds against the node's container, in
+// order, via the runtime's Exec API, returning on the first failure
+// together with the command's stderr output. Each command runs through
+// `sh -c`, so hook authors get real shell semantics (redirection,
+// pipelines, ...), matching ExecFiles and the `exec` hook's doc comment.
+func (d *DefaultNode) ExecCommands(ctx context.Context, logger logging.Logger, cmds []string) error {
+	for _, c := range cmds {
+		cmd := types.NewExecCmd([]string{"sh", "-c", c})
+
+		logger.Debugf("exec %q", c)
+
+		execResult, err := d.Runtime.Exec(ctx, d.Cfg.LongName, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: failed to exec %q: %w", d.Cfg.LongName, c, err)
+		}
+
+		if execResult.GetReturnCode() != 0 {
+			return fmt.Errorf("%s: exec %q failed: %s", d.Cfg.LongName, c, execResult.GetStdErrString())
+		}
+	}
+
+	return nil
+}
+
+// ExecFiles renders each file in files inside the node's container, via the
+// runtime's Exec API, returning on the first failure. Content is base64-encoded
+// into the exec'd command so arbitrary (including multi-line) content
+// survives the trip without shell-quoting concerns.
+func (d *DefaultNode) ExecFiles(ctx context.Context, logger logging.Logger, files []types.PostDeployFile) error {
+	for _, f := range files {
+		logger.Debugf("render file %s", f.Path)
+
+		encoded := base64.StdEncoding.EncodeToString([]byte(f.Content))
+		script := fmt.Sprintf("mkdir -p %q && echo %s | base64 -d > %q", filepath.Dir(f.Path), encoded, f.Path)
+		cmd := types.NewExecCmd([]string{"sh", "-c", script})
+
+		execResult, err := d.Runtime.Exec(ctx, d.Cfg.LongName, cmd)
+		if err != nil {
+			return fmt.Errorf("%s: failed to render file %s: %w", d.Cfg.LongName, f.Path, err)
+		}
+
+		if execResult.GetReturnCode() != 0 {
+			return fmt.Errorf("%s: rendering file %s failed: %s", d.Cfg.LongName, f.Path, execResult.GetStdErrString())
+		}
+	}
+
+	return nil
+}
+
+// PostDeploy is the default post-deploy behaviour shared by kinds that don't
+// need anything beyond waiting for the container to come up and running the
+// post-deploy hooks declared on the node (see types.PostDeployHook). Kinds
+// that embed DefaultNode and don't override PostDeploy get this for free.
+func (d *DefaultNode) PostDeploy(ctx context.Context, params *PostDeployParams) error {
+	logger := params.Logger.With("node", d.Cfg.ShortName, "kind", d.Cfg.Kind, "phase", "post-deploy", "container_id", d.Cfg.LongName)
+
+	if err := d.WaitRunning(ctx, logger); err != nil {
+		return err
+	}
+
+	for _, hook := range d.Cfg.PostDeployHooks {
+		if err := d.ExecSysctls(ctx, logger, hook.Sysctls); err != nil {
+			return err
+		}
+		if err := d.ExecCommands(ctx, logger, hook.Exec); err != nil {
+			return err
+		}
+		if err := d.ExecFiles(ctx, logger, hook.Files); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,372 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package nodes
+
+import (
+	"context"
+	"encoding/base64"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/srl-labs/containerlab/pkg/logging"
+	"github.com/srl-labs/containerlab/runtime"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// fakeRuntime is a runtime.Runtime double that returns a scripted container
+// status and records/answers Exec calls, so DefaultNode's post-deploy
+// helpers can be tested without a real container runtime.
+type fakeRuntime struct {
+	mu sync.Mutex
+
+	status ContainerStatusFunc
+	execFn func(cmd *types.ExecCmd) (*types.ExecResult, error)
+
+	execCalls [][]string
+}
+
+// ContainerStatusFunc lets a test script GetContainerStatus's return value,
+// e.g. to simulate a container that starts out not-running.
+type ContainerStatusFunc func() runtime.ContainerStatus
+
+func (f *fakeRuntime) GetContainerStatus(_ context.Context, _ string) runtime.ContainerStatus {
+	return f.status()
+}
+
+func (f *fakeRuntime) Exec(_ context.Context, _ string, cmd *types.ExecCmd) (*types.ExecResult, error) {
+	f.mu.Lock()
+	f.execCalls = append(f.execCalls, cmd.GetCmd())
+	f.mu.Unlock()
+
+	if f.execFn != nil {
+		return f.execFn(cmd)
+	}
+
+	result := types.NewExecResult(cmd)
+	result.SetReturnCode(0)
+	return result, nil
+}
+
+// okExecResult returns a successful ExecResult for cmd.
+func okExecResult(cmd *types.ExecCmd) (*types.ExecResult, error) {
+	result := types.NewExecResult(cmd)
+	result.SetReturnCode(0)
+	return result, nil
+}
+
+// failExecResult returns a failing ExecResult for cmd, with stderr set to
+// msg.
+func failExecResult(cmd *types.ExecCmd, msg string) (*types.ExecResult, error) {
+	result := types.NewExecResult(cmd)
+	result.SetReturnCode(1)
+	result.SetStdErr([]byte(msg))
+	return result, nil
+}
+
+// nopLogger returns a Logger that discards everything it's given, for tests
+// that only care about the behaviour around the logging, not the logging
+// itself.
+func nopLogger() logging.Logger {
+	l := logrus.New()
+	l.SetOutput(io.Discard)
+	return logging.FromLogrus(logrus.NewEntry(l))
+}
+
+func TestWaitRunning(t *testing.T) {
+	t.Run("returns immediately once running", func(t *testing.T) {
+		d := &DefaultNode{
+			Cfg:     &types.NodeConfig{ShortName: "r1"},
+			Runtime: &fakeRuntime{status: func() runtime.ContainerStatus { return runtime.Running }},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := d.WaitRunning(ctx, nopLogger()); err != nil {
+			t.Fatalf("WaitRunning() error = %v, want nil", err)
+		}
+	})
+
+	t.Run("times out if container never reports running", func(t *testing.T) {
+		d := &DefaultNode{
+			Cfg:     &types.NodeConfig{ShortName: "r1", LongName: "clab-test-r1"},
+			Runtime: &fakeRuntime{status: func() runtime.ContainerStatus { return runtime.Stopped }},
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := d.WaitRunning(ctx, nopLogger())
+		if err == nil {
+			t.Fatal("WaitRunning() error = nil, want timeout error")
+		}
+		if !strings.Contains(err.Error(), "clab-test-r1") || !strings.Contains(err.Error(), "timed out") {
+			t.Errorf("WaitRunning() error = %q, want it to mention the node and a timeout", err)
+		}
+	})
+}
+
+func TestExecSysctls(t *testing.T) {
+	t.Run("execs every sysctl", func(t *testing.T) {
+		rt := &fakeRuntime{execFn: okExecResult}
+		d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+		sysctls := map[string]string{"net.ipv6.conf.all.disable_ipv6": "1"}
+		if err := d.ExecSysctls(context.Background(), nopLogger(), sysctls); err != nil {
+			t.Fatalf("ExecSysctls() error = %v, want nil", err)
+		}
+
+		if len(rt.execCalls) != 1 {
+			t.Fatalf("got %d exec calls, want 1", len(rt.execCalls))
+		}
+		want := []string{"sysctl", "-w", "net.ipv6.conf.all.disable_ipv6=1"}
+		if strings.Join(rt.execCalls[0], " ") != strings.Join(want, " ") {
+			t.Errorf("exec call = %v, want %v", rt.execCalls[0], want)
+		}
+	})
+
+	t.Run("returns the failing sysctl's stderr", func(t *testing.T) {
+		rt := &fakeRuntime{execFn: func(cmd *types.ExecCmd) (*types.ExecResult, error) {
+			return failExecResult(cmd, "sysctl: permission denied")
+		}}
+		d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+		err := d.ExecSysctls(context.Background(), nopLogger(), map[string]string{"net.foo": "1"})
+		if err == nil {
+			t.Fatal("ExecSysctls() error = nil, want non-nil")
+		}
+		if !strings.Contains(err.Error(), "permission denied") {
+			t.Errorf("ExecSysctls() error = %q, want it to include the command's stderr", err)
+		}
+	})
+}
+
+func TestExecCommands(t *testing.T) {
+	t.Run("execs commands in order", func(t *testing.T) {
+		rt := &fakeRuntime{execFn: okExecResult}
+		d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+		cmds := []string{"ip link set eth0 up", "ip addr flush dev eth0"}
+		if err := d.ExecCommands(context.Background(), nopLogger(), cmds); err != nil {
+			t.Fatalf("ExecCommands() error = %v, want nil", err)
+		}
+
+		if len(rt.execCalls) != 2 {
+			t.Fatalf("got %d exec calls, want 2", len(rt.execCalls))
+		}
+		want := [][]string{{"sh", "-c", cmds[0]}, {"sh", "-c", cmds[1]}}
+		if !reflect.DeepEqual(rt.execCalls, want) {
+			t.Errorf("exec calls = %v, want %v", rt.execCalls, want)
+		}
+	})
+
+	t.Run("runs each command through a shell, honoring redirection", func(t *testing.T) {
+		rt := &fakeRuntime{execFn: okExecResult}
+		d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+		if err := d.ExecCommands(context.Background(), nopLogger(), []string{"echo hi > /tmp/motd"}); err != nil {
+			t.Fatalf("ExecCommands() error = %v, want nil", err)
+		}
+
+		argv := rt.execCalls[0]
+		if len(argv) != 3 || argv[0] != "sh" || argv[1] != "-c" || argv[2] != "echo hi > /tmp/motd" {
+			t.Errorf("exec argv = %v, want [sh -c \"echo hi > /tmp/motd\"]", argv)
+		}
+	})
+
+	t.Run("stops at the first failing command", func(t *testing.T) {
+		rt := &fakeRuntime{execFn: func(cmd *types.ExecCmd) (*types.ExecResult, error) {
+			if strings.Join(cmd.GetCmd(), " ") == "sh -c false" {
+				return failExecResult(cmd, "exit 1")
+			}
+			return okExecResult(cmd)
+		}}
+		d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+		err := d.ExecCommands(context.Background(), nopLogger(), []string{"false", "echo never runs"})
+		if err == nil {
+			t.Fatal("ExecCommands() error = nil, want non-nil")
+		}
+		if len(rt.execCalls) != 1 {
+			t.Errorf("got %d exec calls, want 1 (should stop after the first failure)", len(rt.execCalls))
+		}
+	})
+}
+
+func TestExecFiles(t *testing.T) {
+	rt := &fakeRuntime{execFn: okExecResult}
+	d := &DefaultNode{Cfg: &types.NodeConfig{ShortName: "r1"}, Runtime: rt}
+
+	files := []types.PostDeployFile{
+		{Path: "/etc/motd", Content: "hello\nworld\n"},
+	}
+	if err := d.ExecFiles(context.Background(), nopLogger(), files); err != nil {
+		t.Fatalf("ExecFiles() error = %v, want nil", err)
+	}
+
+	if len(rt.execCalls) != 1 {
+		t.Fatalf("got %d exec calls, want 1", len(rt.execCalls))
+	}
+	argv := rt.execCalls[0]
+	if len(argv) != 3 || argv[0] != "sh" || argv[1] != "-c" {
+		t.Fatalf("exec argv = %v, want [sh -c <script>]", argv)
+	}
+	if !strings.Contains(argv[2], base64.StdEncoding.EncodeToString([]byte(files[0].Content))) {
+		t.Errorf("script %q doesn't base64-encode the file content", argv[2])
+	}
+	if !strings.Contains(argv[2], `"/etc"`) {
+		t.Errorf("script %q doesn't mkdir -p the file's parent directory", argv[2])
+	}
+}
+
+func TestDefaultNodePostDeploy(t *testing.T) {
+	t.Run("runs every hook's sysctls, exec, and files in order", func(t *testing.T) {
+		rt := &fakeRuntime{status: func() runtime.ContainerStatus { return runtime.Running }, execFn: okExecResult}
+		d := &DefaultNode{
+			Cfg: &types.NodeConfig{
+				ShortName: "r1",
+				PostDeployHooks: []types.PostDeployHook{
+					{
+						Sysctls: map[string]string{"net.ipv6.conf.all.disable_ipv6": "1"},
+						Exec:    []string{"ip link set eth0 up"},
+						Files:   []types.PostDeployFile{{Path: "/etc/motd", Content: "hi"}},
+					},
+					{Exec: []string{"echo second-hook"}},
+				},
+			},
+			Runtime: rt,
+		}
+
+		if err := d.PostDeploy(context.Background(), &PostDeployParams{Logger: nopLogger()}); err != nil {
+			t.Fatalf("PostDeploy() error = %v, want nil", err)
+		}
+
+		want := []string{
+			"sysctl -w net.ipv6.conf.all.disable_ipv6=1",
+			"sh -c ip link set eth0 up",
+			"sh -c ", // ExecFiles builds its own argv; just check the sequence slot below
+			"sh -c echo second-hook",
+		}
+		if len(rt.execCalls) != len(want) {
+			t.Fatalf("got %d exec calls, want %d: %v", len(rt.execCalls), len(want), rt.execCalls)
+		}
+		if strings.Join(rt.execCalls[0], " ") != want[0] {
+			t.Errorf("exec call 0 = %v, want %q", rt.execCalls[0], want[0])
+		}
+		if strings.Join(rt.execCalls[1], " ") != want[1] {
+			t.Errorf("exec call 1 = %v, want %q", rt.execCalls[1], want[1])
+		}
+		if rt.execCalls[2][0] != "sh" {
+			t.Errorf("exec call 2 = %v, want the ExecFiles sh -c script", rt.execCalls[2])
+		}
+		if strings.Join(rt.execCalls[3], " ") != want[3] {
+			t.Errorf("exec call 3 = %v, want %q", rt.execCalls[3], want[3])
+		}
+	})
+
+	t.Run("stops at the first hook step that fails", func(t *testing.T) {
+		rt := &fakeRuntime{
+			status: func() runtime.ContainerStatus { return runtime.Running },
+			execFn: func(cmd *types.ExecCmd) (*types.ExecResult, error) {
+				return failExecResult(cmd, "boom")
+			},
+		}
+		d := &DefaultNode{
+			Cfg: &types.NodeConfig{
+				ShortName: "r1",
+				PostDeployHooks: []types.PostDeployHook{
+					{Sysctls: map[string]string{"net.foo": "1"}},
+					{Exec: []string{"echo never runs"}},
+				},
+			},
+			Runtime: rt,
+		}
+
+		err := d.PostDeploy(context.Background(), &PostDeployParams{Logger: nopLogger()})
+		if err == nil {
+			t.Fatal("PostDeploy() error = nil, want non-nil")
+		}
+		if len(rt.execCalls) != 1 {
+			t.Errorf("got %d exec calls, want 1 (should stop at the first failing step)", len(rt.execCalls))
+		}
+	})
+
+	t.Run("returns early if the container never comes up", func(t *testing.T) {
+		rt := &fakeRuntime{status: func() runtime.ContainerStatus { return runtime.Stopped }}
+		d := &DefaultNode{
+			Cfg:     &types.NodeConfig{ShortName: "r1", LongName: "clab-test-r1"},
+			Runtime: rt,
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := d.PostDeploy(ctx, &PostDeployParams{Logger: nopLogger()})
+		if err == nil {
+			t.Fatal("PostDeploy() error = nil, want a WaitRunning timeout error")
+		}
+		if len(rt.execCalls) != 0 {
+			t.Errorf("got %d exec calls, want 0 (hooks shouldn't run before the container is up)", len(rt.execCalls))
+		}
+	})
+}
+
+// recordingLogger is a logging.Logger double that records the keyvals
+// passed to With into a shared slice, so a test can assert on the
+// structured context a caller attaches without depending on any particular
+// Logger implementation's log output.
+type recordingLogger struct {
+	keyvals *[]interface{}
+}
+
+func newRecordingLogger() *recordingLogger {
+	return &recordingLogger{keyvals: &[]interface{}{}}
+}
+
+func (l *recordingLogger) Debugf(string, ...interface{}) {}
+func (l *recordingLogger) Infof(string, ...interface{})  {}
+func (l *recordingLogger) Warnf(string, ...interface{})  {}
+func (l *recordingLogger) Errorf(string, ...interface{}) {}
+
+func (l *recordingLogger) With(keyvals ...interface{}) logging.Logger {
+	*l.keyvals = append(*l.keyvals, keyvals...)
+	return l
+}
+
+func TestDefaultNodePostDeployLoggerContext(t *testing.T) {
+	rl := newRecordingLogger()
+	rt := &fakeRuntime{status: func() runtime.ContainerStatus { return runtime.Running }, execFn: okExecResult}
+	d := &DefaultNode{
+		Cfg:     &types.NodeConfig{ShortName: "r1", Kind: "rare", LongName: "clab-test-r1"},
+		Runtime: rt,
+	}
+
+	if err := d.PostDeploy(context.Background(), &PostDeployParams{Logger: rl}); err != nil {
+		t.Fatalf("PostDeploy() error = %v, want nil", err)
+	}
+
+	recorded := *rl.keyvals
+	got := make(map[interface{}]interface{}, len(recorded)/2)
+	for i := 0; i+1 < len(recorded); i += 2 {
+		got[recorded[i]] = recorded[i+1]
+	}
+
+	want := map[interface{}]interface{}{
+		"node":         "r1",
+		"kind":         "rare",
+		"phase":        "post-deploy",
+		"container_id": "clab-test-r1",
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("logger context[%q] = %v, want %v", k, got[k], v)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package nodes defines the node lifecycle interface every kind (rare,
+// srl, ...) implements, plus DefaultNode, the shared base most kinds embed
+// to get common behaviour for free.
+package nodes
+
+import (
+	"context"
+
+	"github.com/srl-labs/containerlab/pkg/logging"
+	"github.com/srl-labs/containerlab/types"
+)
+
+// CertificateParams carries whatever a node needs to request/load its TLS
+// certificate.
+type CertificateParams struct {
+	CommonName string
+}
+
+// Certificate is the result of LoadOrGenerateCertificate.
+type Certificate struct {
+	Cert []byte
+	Key  []byte
+}
+
+// PreDeployParams is passed to every kind's PreDeploy call.
+type PreDeployParams struct {
+	Cert         *CertificateParams
+	TopologyName string
+	// Logger is the logger this call logs through; the kind attaches its
+	// own node=/kind=/phase= fields to it via With. Kinds should log through
+	// it rather than a package-global logger.
+	Logger logging.Logger
+}
+
+// PostDeployParams is passed to every kind's PostDeploy call.
+type PostDeployParams struct {
+	// Logger is the logger this call logs through; the kind (or
+	// DefaultNode.PostDeploy) attaches node=/kind=/phase=/container_id=
+	// fields to it via With. Kinds should log through it rather than a
+	// package-global logger.
+	Logger logging.Logger
+}
+
+// Endpoint is one side of a link attached to a node.
+type Endpoint interface {
+	GetIfaceName() string
+}
+
+// Node is the lifecycle interface every kind implements.
+type Node interface {
+	Init(cfg *types.NodeConfig, opts ...NodeOption) error
+	PreDeploy(ctx context.Context, params *PreDeployParams) error
+	PostDeploy(ctx context.Context, params *PostDeployParams) error
+}
+
+// NodeOption configures a Node during Init.
+type NodeOption func(Node)
+
+// NodeRegistry maps kind names to node constructors.
+type NodeRegistry struct {
+	kinds map[string]func() Node
+}
+
+// NewNodeRegistry returns an empty NodeRegistry.
+func NewNodeRegistry() *NodeRegistry {
+	return &NodeRegistry{kinds: make(map[string]func() Node)}
+}
+
+// Register registers constructor for every name in kindnames. initFn is
+// reserved for future per-kind initialization and may be nil.
+func (r *NodeRegistry) Register(kindnames []string, constructor func() Node, initFn func(*NodeRegistry)) {
+	for _, k := range kindnames {
+		r.kinds[k] = constructor
+	}
+	if initFn != nil {
+		initFn(r)
+	}
+}
+
+// NewNode constructs a new Node for kind, or (nil, false) if kind is
+// unregistered.
+func (r *NodeRegistry) NewNode(kind string) (Node, bool) {
+	constructor, ok := r.kinds[kind]
+	if !ok {
+		return nil, false
+	}
+	return constructor(), true
+}
@@ -7,10 +7,8 @@ package rare
 import (
 	"context"
 	"fmt"
-	"os/exec"
 	"path/filepath"
-	"time"
-	log "github.com/sirupsen/logrus"
+
 	"github.com/srl-labs/containerlab/nodes"
 	"github.com/srl-labs/containerlab/types"
 	"github.com/srl-labs/containerlab/utils"
@@ -37,7 +35,7 @@ func (n *rare) Init(cfg *types.NodeConfig, opts ...nodes.NodeOption) error {
 	for _, o := range opts {
 		o(n)
 	}
-	
+
 	n.Cfg.Binds = append(n.Cfg.Binds, fmt.Sprint(filepath.Join(n.Cfg.LabDir, "run"), ":/rtr/run"))
 
 	return nil
@@ -54,66 +52,47 @@ func (n *rare) genInterfacesEnv() {
 	}
 }
 
-
 func (n *rare) PreDeploy(ctx context.Context, params *nodes.PreDeployParams) error {
+	log := params.Logger.With("node", n.Cfg.ShortName, "kind", "rare", "phase", "pre-deploy")
+
 	// Generate the interface environment variables
 	n.genInterfacesEnv()
 
 	utils.CreateDirectory(n.Cfg.LabDir, 0777)
 
+	log.Debugf("loading or generating certificate")
 	_, err := n.LoadOrGenerateCertificate(params.Cert, params.TopologyName)
 	if err != nil {
 		return err
 	}
 
+	// Declare our built-in IPv6 disabling as a post-deploy hook instead of
+	// hardcoding it into PostDeploy, so DefaultNode.PostDeploy can run it
+	// the same way it runs any user-declared hook. Done here rather than in
+	// Init since it depends on the per-interface env vars generated above.
+	n.Cfg.PostDeployHooks = append(n.Cfg.PostDeployHooks, types.PostDeployHook{
+		Sysctls: n.disableIPv6Sysctls(),
+	})
+
 	return n.createRAREFiles()
 }
 
-func (n *rare) PostDeploy(ctx context.Context, params *nodes.PostDeployParams) error {
-	// disable IPv6 at runtime for every interface and globally
-	// Retry loop to wait until the container is fully running
-	for {
-		// Check if the container is running
-		cmd := exec.Command("docker", "inspect", "-f", "{{.State.Running}}", n.Cfg.LongName)
-		output, err := cmd.CombinedOutput()
-		if err != nil || string(output) != "true\n" {
-			log.Infof("Container %s not yet running, waiting...\n", n.Cfg.LongName)
-			time.Sleep(2 * time.Second) // Wait a bit and retry
-		} else {
-			break // Container is running, exit the loop
-		}
-	}
-
-	// Proceed with sysctl commands for individual interfaces if container exists
+// disableIPv6Sysctls returns the sysctls that disable IPv6 on every
+// interface rare knows about, plus globally for all/default.
+func (n *rare) disableIPv6Sysctls() map[string]string {
+	sysctls := make(map[string]string)
 	for i := 0; ; i++ {
 		envKey := fmt.Sprintf("CLAB_INTF_%d", i)
-		if iface, ok := n.Cfg.Env[envKey]; ok {
-			// Use os/exec to set the sysctl values after container start
-			cmd := exec.Command("docker", "exec", n.Cfg.LongName, "sysctl", "-w", fmt.Sprintf("net.ipv6.conf.%s.disable_ipv6=1", iface))
-			_, err := cmd.CombinedOutput()
-			if err != nil {
-				return fmt.Errorf("Failed to disable IPv6 on interface %s of container %s", iface, n.Cfg.LongName)
-			}
-		} else {
+		iface, ok := n.Cfg.Env[envKey]
+		if !ok {
 			break // No more interfaces in the environment variables
 		}
+		sysctls[fmt.Sprintf("net.ipv6.conf.%s.disable_ipv6", iface)] = "1"
 	}
+	sysctls["net.ipv6.conf.all.disable_ipv6"] = "1"
+	sysctls["net.ipv6.conf.default.disable_ipv6"] = "1"
 
-	// Disable IPv6 globally for all and default
-	sysctlCommands := []string{
-		"net.ipv6.conf.all.disable_ipv6=1",
-		"net.ipv6.conf.default.disable_ipv6=1",
-	}
-
-	for _, sysctlCmd := range sysctlCommands {
-		cmd := exec.Command("docker", "exec", n.Cfg.LongName, "sysctl", "-w", sysctlCmd)
-		_, err := cmd.CombinedOutput()
-		if err != nil {
-			return fmt.Errorf("Failed to execute sysctl command %s on container %s", sysctlCmd, n.Cfg.LongName)
-		}
-	}
-
-	return nil
+	return sysctls
 }
 
 func (n *rare) createRAREFiles() error {
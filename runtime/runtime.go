@@ -0,0 +1,34 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package runtime defines the container-runtime abstraction containerlab
+// deploys nodes through, so kinds work the same way whether the lab runs on
+// docker, podman, or ignite.
+package runtime
+
+import (
+	"context"
+
+	"github.com/srl-labs/containerlab/types"
+)
+
+// ContainerStatus is the runtime-agnostic status of a node's container.
+type ContainerStatus string
+
+const (
+	Running  ContainerStatus = "running"
+	Stopped  ContainerStatus = "stopped"
+	Paused   ContainerStatus = "paused"
+	NotFound ContainerStatus = "not-found"
+)
+
+// Runtime is implemented by every container runtime containerlab supports
+// (docker, podman, ignite, ...). Kinds should drive containers through this
+// interface rather than shelling out to a specific runtime's CLI.
+type Runtime interface {
+	// GetContainerStatus reports cID's current status.
+	GetContainerStatus(ctx context.Context, cID string) ContainerStatus
+	// Exec runs cmd inside cID and returns its result.
+	Exec(ctx context.Context, cID string, cmd *types.ExecCmd) (*types.ExecResult, error)
+}
@@ -0,0 +1,40 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+// DiscoveryConfig is parsed from the topology's top-level `discovery:`
+// block. Backend selects which of Consul/Etcd/File is used; the others are
+// ignored.
+type DiscoveryConfig struct {
+	// Backend is one of "consul", "etcd" or "file".
+	Backend string                 `yaml:"backend"`
+	Consul  *ConsulDiscoveryConfig `yaml:"consul,omitempty"`
+	Etcd    *EtcdDiscoveryConfig   `yaml:"etcd,omitempty"`
+	File    *FileDiscoveryConfig   `yaml:"file,omitempty"`
+}
+
+// ConsulDiscoveryConfig configures the Consul service-discovery backend.
+type ConsulDiscoveryConfig struct {
+	// Address of the Consul HTTP API, e.g. "127.0.0.1:8500".
+	Address string `yaml:"address"`
+	// Token is an optional ACL token used for registration.
+	Token string `yaml:"token,omitempty"`
+}
+
+// EtcdDiscoveryConfig configures the etcd service-discovery backend.
+type EtcdDiscoveryConfig struct {
+	// Endpoints is the list of etcd cluster members, e.g. "127.0.0.1:2379".
+	Endpoints []string `yaml:"endpoints"`
+	// Prefix is prepended to every key this backend writes.
+	Prefix string `yaml:"prefix,omitempty"`
+}
+
+// FileDiscoveryConfig configures the file-based service-discovery backend,
+// used as a dependency-free fallback for local labs.
+type FileDiscoveryConfig struct {
+	// Path is where the JSON registry is written; defaults to
+	// "discovery.json" in the current working directory when empty.
+	Path string `yaml:"path,omitempty"`
+}
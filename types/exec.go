@@ -0,0 +1,82 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ExecCmd is a single command to run inside a node's container via a
+// runtime's Exec API.
+type ExecCmd struct {
+	cmd []string
+}
+
+// NewExecCmdFromString splits s on whitespace into an ExecCmd. It returns
+// an error for an empty command so callers don't accidentally exec nothing.
+func NewExecCmdFromString(s string) (*ExecCmd, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty exec command")
+	}
+	return &ExecCmd{cmd: fields}, nil
+}
+
+// NewExecCmd builds an ExecCmd from an already-split argv, for callers that
+// need an argument passed through verbatim rather than whitespace-split,
+// e.g. a shell script embedding rendered file content.
+func NewExecCmd(argv []string) *ExecCmd {
+	return &ExecCmd{cmd: argv}
+}
+
+// GetCmd returns the command as an argv slice, suitable for passing to a
+// runtime's Exec API.
+func (c *ExecCmd) GetCmd() []string {
+	return c.cmd
+}
+
+// ExecResult is what a runtime's Exec API returns for a single command.
+type ExecResult struct {
+	cmd        *ExecCmd
+	returnCode int
+	stdOut     []byte
+	stdErr     []byte
+}
+
+// NewExecResult builds an ExecResult for cmd.
+func NewExecResult(cmd *ExecCmd) *ExecResult {
+	return &ExecResult{cmd: cmd}
+}
+
+// SetReturnCode records the command's exit code.
+func (r *ExecResult) SetReturnCode(rc int) {
+	r.returnCode = rc
+}
+
+// SetStdOut records the command's stdout.
+func (r *ExecResult) SetStdOut(b []byte) {
+	r.stdOut = b
+}
+
+// SetStdErr records the command's stderr.
+func (r *ExecResult) SetStdErr(b []byte) {
+	r.stdErr = b
+}
+
+// GetReturnCode returns the command's exit code.
+func (r *ExecResult) GetReturnCode() int {
+	return r.returnCode
+}
+
+// GetStdOutString returns the command's stdout as a string.
+func (r *ExecResult) GetStdOutString() string {
+	return string(r.stdOut)
+}
+
+// GetStdErrString returns the command's stderr as a string.
+func (r *ExecResult) GetStdErrString() string {
+	return string(r.stdErr)
+}
@@ -0,0 +1,47 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+// IPAMConfig is parsed from the topology's top-level `ipam:` block. It
+// configures the IPAM provider config.RenderLink uses to address links,
+// replacing the implicit systemIP-derived scheme with something explicit
+// and user-controlled.
+type IPAMConfig struct {
+	// Provider selects the allocator: "system-ip" (default, preserves the
+	// existing systemIP-label-derived behaviour), "pool", or "external".
+	Provider string `yaml:"provider,omitempty"`
+	// Pool configures the sequential-pool allocator.
+	Pool *IPAMPoolConfig `yaml:"pool,omitempty"`
+	// External configures the external allocator.
+	External *IPAMExternalConfig `yaml:"external,omitempty"`
+}
+
+// IPAMPoolConfig configures the sequential /31 (or /127 for v6) pool
+// allocator. The allocator draws from a single subnet: IPv4Subnet if set,
+// otherwise IPv6Subnet.
+type IPAMPoolConfig struct {
+	// IPv4Subnet is the pool /31s are carved out of, e.g. "172.31.0.0/16".
+	IPv4Subnet string `yaml:"ipv4-subnet,omitempty"`
+	// IPv6Subnet is the pool /127s are carved out of. It is ignored when
+	// IPv4Subnet is set.
+	IPv6Subnet string `yaml:"ipv6-subnet,omitempty"`
+	// ReservationFile persists allocations across `containerlab deploy`
+	// runs; defaults to "<labdir>/ipam-reservations.json", where labdir is
+	// the lab's working directory (see config.NewIPAM).
+	ReservationFile string `yaml:"reservation-file,omitempty"`
+}
+
+// IPAMExternalConfig configures the external allocator, which hands
+// addressing decisions to a user-provided script or HTTP endpoint.
+type IPAMExternalConfig struct {
+	// Script is a path to an executable invoked as
+	// `<script> <linkID> <endpointA> <endpointB>`, expected to print
+	// "<ipA> <ipB>" (CIDR notation) on stdout.
+	Script string `yaml:"script,omitempty"`
+	// URL is an HTTP endpoint POSTed
+	// {"link_id","endpoint_a","endpoint_b"} and expected to respond
+	// {"ip_a","ip_b"} (CIDR notation). Ignored if Script is set.
+	URL string `yaml:"url,omitempty"`
+}
@@ -0,0 +1,32 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+// PostDeployHook describes a single post-deploy action to run against a
+// node's container once the runtime reports it as running, before topology
+// deployment is considered complete. It is parsed from the node's
+// `post-deploy` section in the topology file, and kinds may also append
+// hooks of their own to express built-in behaviour declaratively instead of
+// hardcoding it in Go.
+type PostDeployHook struct {
+	// Sysctls to set via `sysctl -w` inside the container, keyed by
+	// sysctl name, e.g. "net.ipv6.conf.all.disable_ipv6": "1".
+	Sysctls map[string]string `yaml:"sysctls,omitempty"`
+	// Exec is a list of shell commands to run inside the container, in
+	// order, via the runtime's Exec API.
+	Exec []string `yaml:"exec,omitempty"`
+	// Files lists files to render inside the container, in order, via the
+	// runtime's Exec API.
+	Files []PostDeployFile `yaml:"files,omitempty"`
+}
+
+// PostDeployFile is a single file to render inside a node's container as
+// part of a PostDeployHook.
+type PostDeployFile struct {
+	// Path is the absolute path to write Content to inside the container.
+	Path string `yaml:"path"`
+	// Content is the file's contents, written verbatim.
+	Content string `yaml:"content"`
+}
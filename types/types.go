@@ -0,0 +1,25 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package types
+
+// NodeConfig holds the resolved, per-node configuration used to deploy and
+// manage a single lab node, after defaults and topology overrides have been
+// merged in.
+type NodeConfig struct {
+	ShortName string `yaml:"-"`
+	LongName  string `yaml:"-"`
+	Kind      string `yaml:"kind,omitempty"`
+	Image     string `yaml:"image,omitempty"`
+	LabDir    string `yaml:"-"`
+
+	Env   map[string]string `yaml:"env,omitempty"`
+	Binds []string          `yaml:"binds,omitempty"`
+
+	// PostDeployHooks lists the post-deploy actions to run against this
+	// node's container once it is running, parsed from the topology's
+	// `post-deploy` node section as well as any a kind appends on its own
+	// behalf (e.g. rare's built-in IPv6 disabling).
+	PostDeployHooks []PostDeployHook `yaml:"post-deploy,omitempty"`
+}
@@ -0,0 +1,21 @@
+// Copyright 2020 Nokia
+// Licensed under the BSD 3-Clause License.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package utils holds small helpers shared across kinds and packages.
+package utils
+
+import (
+	"os"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// CreateDirectory creates path (and any missing parents) with perm,
+// logging but not failing on error - most callers can't usefully recover
+// from a lab directory that already exists or is unwritable here.
+func CreateDirectory(path string, perm os.FileMode) {
+	if err := os.MkdirAll(path, perm); err != nil {
+		log.Errorf("failed to create directory %s: %v", path, err)
+	}
+}